@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sonjek/helm-s3/internal/action"
+	"github.com/sonjek/helm-s3/internal/awsutil"
+)
+
+func newInitCmd() *cobra.Command {
+	var oci bool
+
+	cmd := &cobra.Command{
+		Use:   "init <s3-uri>",
+		Short: "Initialize a new repository at the given S3 URI",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sess, err := awsutil.New()
+			if err != nil {
+				return err
+			}
+
+			if err := action.Init(sess, action.InitOptions{URI: args[0], OCI: oci}); err != nil {
+				return err
+			}
+
+			fmt.Println("Completed successfully.")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&oci, "oci", false, "Initialize the repository for OCI-artifact mode instead of the flat index.yaml layout.")
+
+	return cmd
+}
@@ -0,0 +1,36 @@
+// Command helm-s3 is a Helm plugin that adds support for using an S3 bucket
+// (or S3-compatible object storage) as a chart repository.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+func main() {
+	cmd := newRootCmd()
+	if err := cmd.Execute(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newRootCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:          "s3",
+		Short:        "helm-s3 manages Helm chart repositories backed by S3",
+		SilenceUsage: true,
+	}
+
+	cmd.AddCommand(
+		newInitCmd(),
+		newPushCmd(),
+		newPullCmd(),
+		newVerifyCmd(),
+		newReindexCmd(),
+	)
+
+	return cmd
+}
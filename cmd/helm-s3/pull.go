@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sonjek/helm-s3/internal/action"
+	"github.com/sonjek/helm-s3/internal/awsutil"
+)
+
+func newPullCmd() *cobra.Command {
+	var (
+		version     string
+		verify      bool
+		keyring     string
+		destination string
+		untar       bool
+		untarDir    string
+		oci         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull <repo>/<chart>",
+		Short: "Fetch a chart directly from S3, without going through `helm fetch`",
+		Long: "Fetch a chart directly from S3 using the plugin's own AWS credentials, " +
+			"which avoids the pre-signed URL round-trip (and its expiry) that `helm " +
+			"fetch` relies on. The downloaded chart's digest is always checked " +
+			"against the one recorded in the index; --verify additionally checks the " +
+			"chart's provenance file signature.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoName, chartName, err := splitRepoChart(args[0])
+			if err != nil {
+				return err
+			}
+
+			sess, err := awsutil.New()
+			if err != nil {
+				return err
+			}
+
+			if oci {
+				if verify {
+					return fmt.Errorf("--verify is not yet supported together with --oci")
+				}
+				if err := action.PullOCI(sess, action.PullOCIOptions{
+					RepoName:     repoName,
+					ChartName:    chartName,
+					ChartVersion: version,
+					Destination:  destination,
+				}); err != nil {
+					return err
+				}
+				fmt.Println("Successfully pulled the chart.")
+				return nil
+			}
+
+			if err := action.Pull(sess, action.PullOptions{
+				RepoName:     repoName,
+				ChartName:    chartName,
+				ChartVersion: version,
+				Verify:       verify,
+				Keyring:      keyring,
+				Destination:  destination,
+				Untar:        untar,
+				UntarDir:     untarDir,
+			}); err != nil {
+				return err
+			}
+
+			fmt.Println("Successfully pulled the chart.")
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&version, "version", "", "Chart version to pull (default: latest).")
+	flags.BoolVar(&verify, "verify", false, "Verify the chart against its provenance file before writing it out.")
+	flags.StringVar(&keyring, "keyring", "", "Path to the public keyring used with --verify (default \"~/.gnupg/pubring.gpg\").")
+	flags.StringVar(&destination, "destination", "", "Directory to write the chart to (default: current directory).")
+	flags.BoolVar(&untar, "untar", false, "Extract the chart archive after fetching it.")
+	flags.StringVar(&untarDir, "untardir", "", "Directory to extract the chart into (default: --destination).")
+	flags.BoolVar(&oci, "oci", false, "Fetch a chart stored as an OCI artifact instead of the flat index.yaml layout.")
+
+	return cmd
+}
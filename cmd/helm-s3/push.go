@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sonjek/helm-s3/internal/action"
+	"github.com/sonjek/helm-s3/internal/awsutil"
+)
+
+func newPushCmd() *cobra.Command {
+	var opts action.PushOptions
+	var recursive bool
+	var parallelism int
+	var oci bool
+
+	cmd := &cobra.Command{
+		Use:   "push <chart-path(s)...> <repo>",
+		Short: "Push a chart to a repository",
+		Long: "Push one or more charts to a repository. <chart-path(s)...> may be a " +
+			"single chart archive, a directory (with --recursive to walk it), or a " +
+			"shell glob matching several archives; in any of those cases the charts " +
+			"are uploaded concurrently and the index is updated once at the end.",
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			paths := args[:len(args)-1]
+			repoName := args[len(args)-1]
+
+			sess, err := awsutil.New()
+			if err != nil {
+				return err
+			}
+
+			if oci {
+				if len(paths) != 1 {
+					return fmt.Errorf("--oci only supports pushing a single chart at a time")
+				}
+				if err := action.PushOCI(sess, action.PushOCIOptions{ChartPath: paths[0], RepoName: repoName}); err != nil {
+					return err
+				}
+				fmt.Println("Successfully uploaded the chart to the repository.")
+				return nil
+			}
+
+			if len(paths) == 1 && !recursive && !isGlobPattern(paths[0]) && !isDir(paths[0]) {
+				opts.ChartPath = paths[0]
+				opts.RepoName = repoName
+				return action.Push(sess, opts)
+			}
+
+			batchOpts := action.PushBatchOptions{
+				Paths:          paths,
+				Recursive:      recursive,
+				RepoName:       repoName,
+				ContentType:    opts.ContentType,
+				ACL:            opts.ACL,
+				Force:          opts.Force,
+				IgnoreIfExists: opts.IgnoreIfExists,
+				DryRun:         opts.DryRun,
+				Relative:       opts.Relative,
+				Parallelism:    parallelism,
+			}
+
+			results, err := action.PushBatch(sess, batchOpts)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range results {
+				if r.Status == action.StatusFailed {
+					return errAnyChartFailed
+				}
+			}
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&opts.ContentType, "content-type", "application/gzip", "Content-Type to set on the uploaded chart object.")
+	flags.StringVar(&opts.ACL, "acl", "", "Canned ACL to apply to the uploaded objects.")
+	flags.BoolVar(&opts.Force, "force", false, "Replace the chart if it already exists. This can cause the repository to lose existing chart(s).")
+	flags.BoolVar(&opts.IgnoreIfExists, "ignore-if-exists", false, "If the chart already exists, exit normally and do not trigger an error.")
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Do not modify remote index or upload the chart, but run all the other steps.")
+	flags.BoolVar(&opts.Relative, "relative", false, "Use relative chart URL in the index instead of absolute.")
+	flags.BoolVar(&recursive, "recursive", false, "Recurse into the given directory and push every chart found in it.")
+	flags.IntVar(&parallelism, "parallelism", 4, "Maximum number of charts to upload concurrently when pushing more than one.")
+	flags.BoolVar(&oci, "oci", false, "Store the chart as an OCI artifact instead of the flat index.yaml layout.")
+
+	return cmd
+}
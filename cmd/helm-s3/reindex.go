@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sonjek/helm-s3/internal/action"
+	"github.com/sonjek/helm-s3/internal/awsutil"
+)
+
+func newReindexCmd() *cobra.Command {
+	var opts action.ReindexOptions
+
+	cmd := &cobra.Command{
+		Use:   "reindex [REPO...]",
+		Short: "Rebuild a repository's index.yaml from the chart archives actually present in S3",
+		Long: "Rebuild one or more repositories' index.yaml by listing every chart " +
+			"archive in their bucket/prefix and recomputing the index from scratch. " +
+			"Repositories are processed concurrently and a failure in one does not " +
+			"prevent the others from being reindexed.",
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts.RepoNames = args
+
+			sess, err := awsutil.New()
+			if err != nil {
+				return err
+			}
+
+			results := action.Reindex(sess, opts)
+
+			var anyErr error
+			for _, r := range results {
+				if r.Err != nil {
+					fmt.Printf("%s: failed: %s\n", r.RepoName, r.Err)
+					anyErr = errAnyChartFailed
+					continue
+				}
+				if r.Diff == "" {
+					fmt.Printf("%s: up to date\n", r.RepoName)
+					continue
+				}
+				fmt.Printf("%s:\n%s", r.RepoName, r.Diff)
+			}
+
+			return anyErr
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.BoolVar(&opts.DryRun, "dry-run", false, "Print the diff between the existing index and the freshly computed one without uploading it.")
+	flags.BoolVar(&opts.Prune, "prune", false, "Remove index entries whose backing object no longer exists in S3.")
+
+	return cmd
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var errAnyChartFailed = errors.New("one or more charts failed, see the summary above")
+
+// isGlobPattern reports whether path contains shell glob metacharacters,
+// e.g. when the caller passed an unexpanded pattern like "./dist/*.tgz" in
+// quotes.
+func isGlobPattern(path string) bool {
+	return strings.ContainsAny(path, "*?[")
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// splitRepoChart splits a "repo/chart" reference as accepted by most of
+// this plugin's commands.
+func splitRepoChart(ref string) (repoName, chartName string, err error) {
+	parts := strings.SplitN(ref, "/", 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", errInvalidChartRef(ref)
+	}
+	return parts[0], parts[1], nil
+}
+
+type errInvalidChartRef string
+
+func (e errInvalidChartRef) Error() string {
+	return "invalid chart reference " + string(e) + ", expected format <repo>/<chart>"
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/sonjek/helm-s3/internal/action"
+	"github.com/sonjek/helm-s3/internal/awsutil"
+)
+
+func newVerifyCmd() *cobra.Command {
+	var opts action.VerifyOptions
+	var version string
+
+	cmd := &cobra.Command{
+		Use:   "verify <repo>/<chart> --version <version>",
+		Short: "Verify a pushed chart against its provenance file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repoName, chartName, err := splitRepoChart(args[0])
+			if err != nil {
+				return err
+			}
+
+			opts.RepoName = repoName
+			opts.ChartName = chartName
+			opts.ChartVersion = version
+			opts.ChartFilename = fmt.Sprintf("%s-%s.tgz", chartName, version)
+
+			sess, err := awsutil.New()
+			if err != nil {
+				return err
+			}
+
+			if err := action.Verify(sess, opts); err != nil {
+				return err
+			}
+
+			fmt.Println("Signed chart is valid.")
+			return nil
+		},
+	}
+
+	flags := cmd.Flags()
+	flags.StringVar(&version, "version", "", "Chart version to verify.")
+	flags.StringVar(&opts.Keyring, "keyring", "", "Path to the public keyring used to verify the signature (default \"~/.gnupg/pubring.gpg\").")
+	_ = cmd.MarkFlagRequired("version")
+
+	return cmd
+}
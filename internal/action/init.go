@@ -0,0 +1,40 @@
+package action
+
+import (
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// InitOptions holds everything needed to initialize a new repository.
+type InitOptions struct {
+	URI string
+	OCI bool
+}
+
+// Init prepares a bucket/prefix to be used as a helm-s3 repository.
+//
+// In the default, flat layout this means uploading an empty index.yaml so
+// that subsequent `helm s3 push` calls have something to fetch and modify.
+// In OCI mode there is no index.yaml: charts pushed with `--oci` are
+// addressed directly by name and version, so Init only validates that the
+// given URI is well-formed.
+func Init(sess *session.Session, opts InitOptions) error {
+	bucket, prefix, err := helmutil.SplitS3URL(opts.URI)
+	if err != nil {
+		return err
+	}
+
+	if opts.OCI {
+		return nil
+	}
+
+	idx := repo.NewIndexFile()
+	if err := helmutil.SaveIndex(sess, bucket, prefix, idx); err != nil {
+		return errors.Wrap(err, "initialize repository")
+	}
+
+	return nil
+}
@@ -0,0 +1,136 @@
+package action
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// PullOptions holds everything needed to fetch a chart directly from S3.
+type PullOptions struct {
+	RepoName     string
+	ChartName    string
+	ChartVersion string // empty means the latest version
+	Verify       bool
+	Keyring      string
+	Destination  string
+	Untar        bool
+	UntarDir     string
+}
+
+// Pull resolves the chart via the repository's (cached) index.yaml, then
+// downloads it straight from S3 using the plugin's own AWS credentials,
+// bypassing `helm fetch` and its pre-signed-URL round trip entirely. The
+// downloaded chart's digest is always checked against the one recorded in
+// the index; with --verify, the chart's provenance file is downloaded too
+// and its signature is checked against keyring.
+func Pull(sess *session.Session, opts PullOptions) error {
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	idx, err := helmutil.LoadCachedIndex(opts.RepoName)
+	if err != nil {
+		return err
+	}
+	if idx == nil {
+		idx, err = helmutil.FetchIndex(sess, bucket, prefix)
+		if err != nil {
+			return err
+		}
+		if err := helmutil.CacheIndex(opts.RepoName, idx); err != nil {
+			return err
+		}
+	}
+
+	cv, err := idx.Get(opts.ChartName, opts.ChartVersion)
+	if err != nil {
+		return errors.Wrapf(err, "chart %q version %q not found in repository %q", opts.ChartName, opts.ChartVersion, opts.RepoName)
+	}
+	if len(cv.URLs) == 0 {
+		return errors.Errorf("chart %q version %q has no URLs in the index", opts.ChartName, cv.Version)
+	}
+
+	chartFilename := filepath.Base(cv.URLs[0])
+
+	chartPath, err := downloadToTemp(sess, bucket, helmutil.JoinKey(prefix, chartFilename), chartFilename)
+	if err != nil {
+		return errors.Wrap(err, "download chart")
+	}
+	defer os.Remove(chartPath)
+
+	if cv.Digest != "" {
+		gotDigest, err := provenance.DigestFile(chartPath)
+		if err != nil {
+			return errors.Wrap(err, "compute chart digest")
+		}
+		if gotDigest != cv.Digest {
+			return errors.Errorf("chart digest mismatch: expected %s, got %s", cv.Digest, gotDigest)
+		}
+	}
+
+	if opts.Verify {
+		provFilename := chartFilename + ProvenanceSuffix
+		provPath, err := downloadToTemp(sess, bucket, helmutil.JoinKey(prefix, provFilename), provFilename)
+		if err != nil {
+			return errors.Wrap(err, "download provenance file")
+		}
+		defer os.Remove(provPath)
+
+		provData, err := ioutil.ReadFile(provPath)
+		if err != nil {
+			return errors.Wrap(err, "read provenance file")
+		}
+
+		keyring := opts.Keyring
+		if keyring == "" {
+			keyring = defaultKeyring
+		}
+		if err := verifyProvenance(chartPath, chartFilename, provData, keyring); err != nil {
+			return err
+		}
+	}
+
+	destination := opts.Destination
+	if destination == "" {
+		destination = "."
+	}
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return errors.Wrapf(err, "create destination directory %s", destination)
+	}
+
+	chartBytes, err := ioutil.ReadFile(chartPath)
+	if err != nil {
+		return errors.Wrap(err, "read downloaded chart")
+	}
+
+	destPath := filepath.Join(destination, chartFilename)
+	if err := ioutil.WriteFile(destPath, chartBytes, 0644); err != nil {
+		return errors.Wrapf(err, "write chart to %s", destPath)
+	}
+
+	if opts.Untar {
+		untarDir := opts.UntarDir
+		if untarDir == "" {
+			untarDir = destination
+		}
+		if err := chartutil.ExpandFile(untarDir, destPath); err != nil {
+			return errors.Wrap(err, "untar chart")
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package action
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+	"github.com/sonjek/helm-s3/internal/ociutil"
+)
+
+// PullOCIOptions holds everything needed to fetch a chart stored as an OCI
+// artifact directly from S3.
+type PullOCIOptions struct {
+	RepoName     string
+	ChartName    string
+	ChartVersion string
+	Destination  string
+}
+
+// PullOCI resolves the chart's manifest by tag, fetches its chart layer by
+// digest, and writes it to opts.Destination.
+func PullOCI(sess *session.Session, opts PullOCIOptions) error {
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	manifest, err := ociutil.GetManifest(sess, bucket, prefix, opts.ChartName, opts.ChartVersion)
+	if err != nil {
+		return errors.Wrap(err, "fetch manifest")
+	}
+
+	var layer *ociutil.Descriptor
+	for i := range manifest.Layers {
+		if manifest.Layers[i].MediaType == ociutil.MediaTypeChartLayer {
+			layer = &manifest.Layers[i]
+			break
+		}
+	}
+	if layer == nil {
+		return errors.Errorf("manifest for %s:%s has no chart layer", opts.ChartName, opts.ChartVersion)
+	}
+
+	chartBytes, err := ociutil.GetBlob(sess, bucket, prefix, opts.ChartName, layer.Digest)
+	if err != nil {
+		return errors.Wrap(err, "fetch chart layer")
+	}
+
+	if gotDigest := ociutil.Digest(chartBytes); gotDigest != layer.Digest {
+		return errors.Errorf("chart digest mismatch: expected %s, got %s", layer.Digest, gotDigest)
+	}
+
+	destination := opts.Destination
+	if destination == "" {
+		destination = "."
+	}
+	if err := os.MkdirAll(destination, 0755); err != nil {
+		return errors.Wrapf(err, "create destination directory %s", destination)
+	}
+
+	chartFilename := opts.ChartName + "-" + opts.ChartVersion + ".tgz"
+	destPath := filepath.Join(destination, chartFilename)
+
+	if err := os.WriteFile(destPath, chartBytes, 0644); err != nil {
+		return errors.Wrapf(err, "write chart to %s", destPath)
+	}
+
+	return nil
+}
@@ -0,0 +1,217 @@
+// Package action implements the business logic behind the plugin's
+// subcommands, independent of how they are invoked from the CLI.
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// ProvenanceSuffix is the conventional suffix Helm uses for a chart's
+// detached signature file.
+const ProvenanceSuffix = ".prov"
+
+const provenanceContentType = "application/pgp-signature"
+
+// errChartExists is returned internally when a chart already exists in the
+// repository and neither --force nor --ignore-if-exists applies.
+var errChartExists = errors.New("The chart already exists in the repository and cannot be overwritten without an explicit intent.")
+
+// PushOptions holds everything needed to push a chart to a repository.
+type PushOptions struct {
+	ChartPath      string
+	RepoName       string
+	ContentType    string
+	ACL            string
+	Force          bool
+	IgnoreIfExists bool
+	DryRun         bool
+	Relative       bool
+}
+
+// Push uploads the chart (and, when present, its provenance file) found at
+// opts.ChartPath to the repository registered under opts.RepoName, then
+// updates that repository's index.yaml accordingly.
+func Push(sess *session.Session, opts PushOptions) error {
+	if opts.Force && opts.IgnoreIfExists {
+		return errors.New("The --force and --ignore-if-exists flags are mutually exclusive and cannot be specified together.")
+	}
+
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	idx, err := helmutil.FetchIndex(sess, bucket, prefix)
+	if err != nil {
+		return err
+	}
+
+	p := preparedChart{path: opts.ChartPath}
+	if err := p.prepare(); err != nil {
+		return err
+	}
+	if err := p.checkExists(idx, opts.Force, opts.IgnoreIfExists); err != nil {
+		return err
+	}
+
+	if p.skip {
+		fmt.Println("The chart already exists in the repository, keep existing chart and ignore push.")
+		return nil
+	}
+
+	if !opts.DryRun {
+		if err := p.upload(sess, bucket, prefix, opts.ContentType, opts.ACL); err != nil {
+			return err
+		}
+
+		if err := p.addToIndex(idx, entry.URL, opts.Relative); err != nil {
+			return err
+		}
+		idx.SortEntries()
+		idx.Generated = time.Now()
+
+		if err := helmutil.SaveIndex(sess, bucket, prefix, idx); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println("Successfully uploaded the chart to the repository.")
+	return nil
+}
+
+// preparedChart carries everything needed to upload a single chart and
+// register it in the index, computed once up front so batch pushes can do
+// their index bookkeeping after all uploads finish.
+type preparedChart struct {
+	path     string
+	meta     *chart.Metadata
+	digest   string
+	hasProv  bool
+	skip     bool
+	filename string
+}
+
+// prepare loads the chart and computes its digest. It does not touch idx and
+// is safe to call concurrently for different charts.
+func (p *preparedChart) prepare() error {
+	c, err := loader.Load(p.path)
+	if err != nil {
+		return errors.Wrapf(err, "load chart %s", p.path)
+	}
+	p.meta = c.Metadata
+	p.filename = filepath.Base(p.path)
+
+	digest, err := provenance.DigestFile(p.path)
+	if err != nil {
+		return errors.Wrapf(err, "compute digest of %s", p.path)
+	}
+	p.digest = digest
+	p.hasProv = fileExists(p.path + ProvenanceSuffix)
+
+	return nil
+}
+
+// checkExists looks p up in idx and sets p.skip according to force/
+// ignoreIfExists. Callers sharing idx across goroutines must serialize calls
+// to checkExists (e.g. with a mutex); it is the only part of the pipeline
+// that reads index state.
+func (p *preparedChart) checkExists(idx *repo.IndexFile, force, ignoreIfExists bool) error {
+	if idx.Has(p.meta.Name, p.meta.Version) && !force {
+		if ignoreIfExists {
+			p.skip = true
+			return nil
+		}
+		return errChartExists
+	}
+
+	return nil
+}
+
+func (p *preparedChart) upload(sess *session.Session, bucket, prefix, contentType, acl string) error {
+	if err := uploadFile(sess, bucket, helmutil.JoinKey(prefix, p.filename), p.path, contentType, acl); err != nil {
+		return errors.Wrapf(err, "upload chart %s", p.filename)
+	}
+
+	if p.hasProv {
+		provFilename := p.filename + ProvenanceSuffix
+		if err := uploadFile(sess, bucket, helmutil.JoinKey(prefix, provFilename), p.path+ProvenanceSuffix, provenanceContentType, acl); err != nil {
+			return errors.Wrapf(err, "upload provenance file %s", provFilename)
+		}
+	}
+
+	return nil
+}
+
+// addToIndex registers the chart in idx under repoURL, replacing any
+// existing entry for the same name and version. When relative is set, the
+// entry's URL is the bare filename rather than repoURL joined with it, so
+// the index can be used irrespective of where the repository itself is
+// mounted.
+func (p *preparedChart) addToIndex(idx *repo.IndexFile, repoURL string, relative bool) error {
+	idx.Entries[p.meta.Name] = removeVersion(idx.Entries[p.meta.Name], p.meta.Version)
+
+	baseURL := repoURL
+	if relative {
+		baseURL = ""
+	}
+	if err := idx.MustAdd(p.meta, p.filename, baseURL, p.digest); err != nil {
+		return errors.Wrap(err, "add chart to index")
+	}
+	return nil
+}
+
+func uploadFile(sess *session.Session, bucket, key, path, contentType, acl string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return errors.Wrapf(err, "open %s", path)
+	}
+	defer f.Close()
+
+	uploader := s3manager.NewUploader(sess)
+	input := &s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        f,
+		ContentType: aws.String(contentType),
+	}
+	if acl != "" {
+		input.ACL = aws.String(acl)
+	}
+
+	_, err = uploader.Upload(input)
+	return err
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func removeVersion(versions []*repo.ChartVersion, version string) []*repo.ChartVersion {
+	out := versions[:0]
+	for _, v := range versions {
+		if v.Version != version {
+			out = append(out, v)
+		}
+	}
+	return out
+}
@@ -0,0 +1,264 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// PushBatchOptions holds everything needed to push many charts to a
+// repository in a single command invocation.
+type PushBatchOptions struct {
+	Paths          []string
+	Recursive      bool
+	RepoName       string
+	ContentType    string
+	ACL            string
+	Force          bool
+	IgnoreIfExists bool
+	DryRun         bool
+	Relative       bool
+	Parallelism    int
+}
+
+// PushResult describes the outcome of pushing a single chart as part of a
+// batch.
+type PushResult struct {
+	ChartPath string
+	Status    string // "uploaded", "skipped" or "failed"
+	Err       error
+}
+
+const (
+	StatusUploaded = "uploaded"
+	StatusSkipped  = "skipped"
+	StatusFailed   = "failed"
+)
+
+// PushBatch resolves opts.Paths (files, directories or globs) to a set of
+// chart archives, uploads all of them concurrently (bounded by
+// opts.Parallelism) and rolls the resulting index.yaml changes into a
+// single PUT once every upload has finished.
+func PushBatch(sess *session.Session, opts PushBatchOptions) ([]PushResult, error) {
+	if opts.Force && opts.IgnoreIfExists {
+		return nil, errors.New("The --force and --ignore-if-exists flags are mutually exclusive and cannot be specified together.")
+	}
+
+	chartPaths, err := resolveChartPaths(opts.Paths, opts.Recursive)
+	if err != nil {
+		return nil, err
+	}
+	if len(chartPaths) == 0 {
+		return nil, errors.New("no charts found matching the given paths")
+	}
+
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return nil, err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := helmutil.FetchIndex(sess, bucket, prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	parallelism := opts.Parallelism
+	if parallelism <= 0 {
+		parallelism = 4
+	}
+
+	results := make([]PushResult, len(chartPaths))
+	sem := make(chan struct{}, parallelism)
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		uploaded int
+	)
+
+	for i, path := range chartPaths {
+		wg.Add(1)
+		go func(i int, path string) {
+			defer wg.Done()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			result := PushResult{ChartPath: path}
+
+			p := preparedChart{path: path}
+
+			var prepErr error
+			if prepErr = p.prepare(); prepErr == nil {
+				mu.Lock()
+				prepErr = p.checkExists(idx, opts.Force, opts.IgnoreIfExists)
+				mu.Unlock()
+			}
+
+			switch {
+			case prepErr != nil:
+				result.Status = StatusFailed
+				result.Err = prepErr
+			case p.skip:
+				result.Status = StatusSkipped
+			case opts.DryRun:
+				result.Status = StatusUploaded
+			default:
+				if err := p.upload(sess, bucket, prefix, opts.ContentType, opts.ACL); err != nil {
+					result.Status = StatusFailed
+					result.Err = err
+					break
+				}
+
+				mu.Lock()
+				var err error
+				if !opts.Force && idx.Has(p.meta.Name, p.meta.Version) {
+					err = errors.Errorf("chart %s-%s was pushed concurrently by another entry in this batch", p.meta.Name, p.meta.Version)
+				} else {
+					err = p.addToIndex(idx, entry.URL, opts.Relative)
+				}
+				if err == nil {
+					uploaded++
+				}
+				mu.Unlock()
+				if err != nil {
+					result.Status = StatusFailed
+					result.Err = err
+					break
+				}
+
+				result.Status = StatusUploaded
+			}
+
+			results[i] = result
+		}(i, path)
+	}
+
+	wg.Wait()
+
+	if uploaded > 0 && !opts.DryRun {
+		idx.SortEntries()
+		idx.Generated = time.Now()
+
+		if err := helmutil.SaveIndex(sess, bucket, prefix, idx); err != nil {
+			return results, err
+		}
+	}
+
+	printSummary(results)
+
+	return results, nil
+}
+
+func printSummary(results []PushResult) {
+	for _, r := range results {
+		switch r.Status {
+		case StatusUploaded:
+			fmt.Printf("%s: uploaded\n", r.ChartPath)
+		case StatusSkipped:
+			fmt.Printf("%s: skipped (already exists)\n", r.ChartPath)
+		case StatusFailed:
+			fmt.Printf("%s: failed: %s\n", r.ChartPath, r.Err)
+		}
+	}
+
+	var uploaded, skipped, failed int
+	for _, r := range results {
+		switch r.Status {
+		case StatusUploaded:
+			uploaded++
+		case StatusSkipped:
+			skipped++
+		case StatusFailed:
+			failed++
+		}
+	}
+
+	fmt.Printf("Summary: %d uploaded, %d skipped, %d failed.\n", uploaded, skipped, failed)
+}
+
+// resolveChartPaths expands directories and glob patterns in paths into a
+// sorted, de-duplicated list of chart archive files.
+func resolveChartPaths(paths []string, recursive bool) ([]string, error) {
+	seen := make(map[string]struct{})
+	var out []string
+
+	add := func(path string) {
+		if filepath.Ext(path) != ".tgz" {
+			return
+		}
+		if _, ok := seen[path]; ok {
+			return
+		}
+		seen[path] = struct{}{}
+		out = append(out, path)
+	}
+
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		switch {
+		case err == nil && info.IsDir():
+			var matches []string
+			if recursive {
+				matches, err = globRecursive(path)
+				if err != nil {
+					return nil, err
+				}
+			} else {
+				matches, err = filepath.Glob(filepath.Join(path, "*.tgz"))
+				if err != nil {
+					return nil, errors.Wrapf(err, "glob %s", path)
+				}
+			}
+			for _, m := range matches {
+				add(m)
+			}
+		case err == nil:
+			add(path)
+		default:
+			matches, globErr := filepath.Glob(path)
+			if globErr != nil || len(matches) == 0 {
+				return nil, errors.Wrapf(err, "resolve chart path %s", path)
+			}
+			for _, m := range matches {
+				add(m)
+			}
+		}
+	}
+
+	sort.Strings(out)
+	return out, nil
+}
+
+// globRecursive returns every .tgz file found anywhere under dir.
+func globRecursive(dir string) ([]string, error) {
+	var matches []string
+
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() && filepath.Ext(path) == ".tgz" {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "walk %s", dir)
+	}
+
+	return matches, nil
+}
@@ -0,0 +1,77 @@
+package action
+
+import (
+	"encoding/json"
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+	"github.com/sonjek/helm-s3/internal/ociutil"
+)
+
+// PushOCIOptions holds everything needed to push a chart to a repository
+// laid out as OCI artifacts instead of a flat index.yaml.
+type PushOCIOptions struct {
+	ChartPath string
+	RepoName  string
+}
+
+// PushOCI uploads the chart at opts.ChartPath as an OCI artifact: a config
+// blob and a chart-archive layer blob, both addressed by digest, plus a
+// manifest tagged with the chart's version.
+func PushOCI(sess *session.Session, opts PushOCIOptions) error {
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	chart, err := loader.Load(opts.ChartPath)
+	if err != nil {
+		return errors.Wrapf(err, "load chart %s", opts.ChartPath)
+	}
+
+	chartBytes, err := os.ReadFile(opts.ChartPath)
+	if err != nil {
+		return errors.Wrapf(err, "read chart %s", opts.ChartPath)
+	}
+
+	configBytes, err := json.Marshal(ociutil.ChartConfig{
+		Name:        chart.Metadata.Name,
+		Version:     chart.Metadata.Version,
+		Description: chart.Metadata.Description,
+	})
+	if err != nil {
+		return errors.Wrap(err, "marshal chart config")
+	}
+
+	configDesc, err := ociutil.PutBlob(sess, bucket, prefix, chart.Metadata.Name, configBytes, ociutil.MediaTypeChartConfig)
+	if err != nil {
+		return errors.Wrap(err, "upload config blob")
+	}
+
+	layerDesc, err := ociutil.PutBlob(sess, bucket, prefix, chart.Metadata.Name, chartBytes, ociutil.MediaTypeChartLayer)
+	if err != nil {
+		return errors.Wrap(err, "upload chart layer blob")
+	}
+
+	manifest := ociutil.Manifest{
+		SchemaVersion: 2,
+		MediaType:     ociutil.MediaTypeManifest,
+		Config:        configDesc,
+		Layers:        []ociutil.Descriptor{layerDesc},
+	}
+
+	if err := ociutil.PutManifest(sess, bucket, prefix, chart.Metadata.Name, chart.Metadata.Version, manifest); err != nil {
+		return errors.Wrap(err, "upload manifest")
+	}
+
+	return nil
+}
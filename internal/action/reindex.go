@@ -0,0 +1,204 @@
+package action
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// ReindexOptions holds everything needed to rebuild one or more
+// repositories' index.yaml from the objects actually present in S3.
+type ReindexOptions struct {
+	RepoNames []string
+	DryRun    bool
+	Prune     bool
+}
+
+// ReindexResult describes the outcome of reindexing a single repository.
+type ReindexResult struct {
+	RepoName string
+	Diff     string
+	Err      error
+}
+
+// Reindex walks each named repository's bucket/prefix, rebuilds its
+// index.yaml from the chart archives it finds there, and reports one
+// ReindexResult per repository. A failure reindexing one repository does
+// not prevent the others from being processed.
+func Reindex(sess *session.Session, opts ReindexOptions) []ReindexResult {
+	results := make([]ReindexResult, len(opts.RepoNames))
+
+	var wg sync.WaitGroup
+	for i, name := range opts.RepoNames {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			diff, err := reindexOne(sess, name, opts.DryRun, opts.Prune)
+			results[i] = ReindexResult{RepoName: name, Diff: diff, Err: err}
+		}(i, name)
+	}
+	wg.Wait()
+
+	return results
+}
+
+func reindexOne(sess *session.Session, repoName string, dryRun, prune bool) (string, error) {
+	entry, err := helmutil.LookupRepoEntry(repoName)
+	if err != nil {
+		return "", err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return "", err
+	}
+
+	objectKeys, err := listChartObjects(sess, bucket, prefix)
+	if err != nil {
+		return "", err
+	}
+
+	oldIdx, err := helmutil.FetchIndex(sess, bucket, prefix)
+	if err != nil {
+		// A repository that has never been pushed to has no index.yaml yet;
+		// reindex should still be able to build one from scratch.
+		oldIdx = repo.NewIndexFile()
+	}
+
+	newIdx := repo.NewIndexFile()
+	for _, key := range objectKeys {
+		if err := addChartToIndex(sess, bucket, key, entry.URL, newIdx); err != nil {
+			return "", errors.Wrapf(err, "index chart %s", key)
+		}
+	}
+
+	if !prune {
+		carryForwardOrphans(oldIdx, newIdx, objectKeys)
+	}
+
+	newIdx.SortEntries()
+	newIdx.Generated = time.Now()
+
+	diff := diffIndexes(oldIdx, newIdx)
+
+	if dryRun {
+		return diff, nil
+	}
+
+	if err := helmutil.SaveIndex(sess, bucket, prefix, newIdx); err != nil {
+		return diff, err
+	}
+
+	return diff, nil
+}
+
+// listChartObjects returns the keys of every .tgz object under bucket/prefix.
+func listChartObjects(sess *session.Session, bucket, prefix string) ([]string, error) {
+	client := s3.New(sess)
+
+	var keys []string
+	err := client.ListObjectsV2Pages(&s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, obj := range page.Contents {
+			if path.Ext(*obj.Key) == ".tgz" {
+				keys = append(keys, *obj.Key)
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "list chart objects")
+	}
+
+	return keys, nil
+}
+
+// addChartToIndex downloads the chart object at key, computes its digest and
+// registers it in idx under repoURL.
+func addChartToIndex(sess *session.Session, bucket, key, repoURL string, idx *repo.IndexFile) error {
+	tmpPath, err := downloadToTemp(sess, bucket, key, path.Base(key))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmpPath)
+
+	chart, err := loader.Load(tmpPath)
+	if err != nil {
+		return errors.Wrapf(err, "load chart %s", key)
+	}
+
+	digest, err := provenance.DigestFile(tmpPath)
+	if err != nil {
+		return errors.Wrapf(err, "compute digest of %s", key)
+	}
+
+	if err := idx.MustAdd(chart.Metadata, path.Base(key), repoURL, digest); err != nil {
+		return errors.Wrap(err, "add chart to index")
+	}
+
+	return nil
+}
+
+// diffIndexes renders a human-readable summary of what reindexing would
+// add, update or remove compared to the existing index.yaml.
+func diffIndexes(oldIdx, newIdx *repo.IndexFile) string {
+	var out string
+
+	for name, versions := range newIdx.Entries {
+		for _, v := range versions {
+			if !oldIdx.Has(name, v.Version) {
+				out += fmt.Sprintf("+ %s-%s\n", name, v.Version)
+			}
+		}
+	}
+
+	for name, versions := range oldIdx.Entries {
+		for _, v := range versions {
+			if !newIdx.Has(name, v.Version) {
+				out += fmt.Sprintf("- %s-%s\n", name, v.Version)
+			}
+		}
+	}
+
+	return out
+}
+
+// carryForwardOrphans copies index entries from oldIdx into newIdx whose
+// backing object is no longer present among objectKeys. Without --prune,
+// reindex should only ever add to or update an index, never silently drop
+// entries just because rebuilding from live S3 state missed them.
+func carryForwardOrphans(oldIdx, newIdx *repo.IndexFile, objectKeys []string) {
+	present := make(map[string]struct{}, len(objectKeys))
+	for _, k := range objectKeys {
+		present[path.Base(k)] = struct{}{}
+	}
+
+	for name, versions := range oldIdx.Entries {
+		for _, v := range versions {
+			orphaned := true
+			for _, u := range v.URLs {
+				if _, ok := present[path.Base(u)]; ok {
+					orphaned = false
+					break
+				}
+			}
+			if orphaned {
+				newIdx.Entries[name] = append(newIdx.Entries[name], v)
+			}
+		}
+	}
+}
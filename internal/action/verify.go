@@ -0,0 +1,158 @@
+package action
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"regexp"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/openpgp"
+	"golang.org/x/crypto/openpgp/clearsign"
+	"helm.sh/helm/v3/pkg/provenance"
+
+	"github.com/sonjek/helm-s3/internal/helmutil"
+)
+
+// defaultKeyring is used when VerifyOptions.Keyring is left empty, matching
+// the default `helm verify`/`helm package --sign` expects.
+const defaultKeyring = "~/.gnupg/pubring.gpg"
+
+// VerifyOptions holds everything needed to verify a chart already pushed to
+// a repository against its provenance file.
+type VerifyOptions struct {
+	RepoName      string
+	ChartName     string
+	ChartVersion  string
+	ChartFilename string
+	Keyring       string
+}
+
+// Verify downloads the chart and its provenance file from the repository,
+// recomputes the chart's digest and checks it against the one recorded in
+// the provenance file, then validates the provenance's OpenPGP signature
+// against the given keyring.
+func Verify(sess *session.Session, opts VerifyOptions) error {
+	keyring := opts.Keyring
+	if keyring == "" {
+		keyring = defaultKeyring
+	}
+
+	entry, err := helmutil.LookupRepoEntry(opts.RepoName)
+	if err != nil {
+		return err
+	}
+
+	bucket, prefix, err := helmutil.SplitS3URL(entry.URL)
+	if err != nil {
+		return err
+	}
+
+	chartPath, err := downloadToTemp(sess, bucket, helmutil.JoinKey(prefix, opts.ChartFilename), opts.ChartFilename)
+	if err != nil {
+		return errors.Wrap(err, "download chart")
+	}
+	defer os.Remove(chartPath)
+
+	provFilename := opts.ChartFilename + ProvenanceSuffix
+	provPath, err := downloadToTemp(sess, bucket, helmutil.JoinKey(prefix, provFilename), provFilename)
+	if err != nil {
+		return errors.Wrap(err, "download provenance file")
+	}
+	defer os.Remove(provPath)
+
+	provData, err := ioutil.ReadFile(provPath)
+	if err != nil {
+		return errors.Wrap(err, "read provenance file")
+	}
+
+	return verifyProvenance(chartPath, opts.ChartFilename, provData, keyring)
+}
+
+// verifyProvenance checks that chartPath's digest matches the one recorded
+// for chartFilename in provData's files block, then validates provData's
+// OpenPGP signature against keyring. It is shared by the `verify` command
+// and `pull --verify`.
+func verifyProvenance(chartPath, chartFilename string, provData []byte, keyring string) error {
+	block, _ := clearsign.Decode(provData)
+	if block == nil {
+		return errors.New("provenance file is not a valid clearsigned message")
+	}
+
+	wantDigest, err := fileDigest(block.Plaintext, chartFilename)
+	if err != nil {
+		return err
+	}
+
+	gotDigest, err := provenance.DigestFile(chartPath)
+	if err != nil {
+		return errors.Wrap(err, "compute chart digest")
+	}
+
+	if gotDigest != wantDigest {
+		return errors.Errorf("chart digest mismatch: expected sha256:%s, got sha256:%s", wantDigest, gotDigest)
+	}
+
+	keyringFile, err := os.Open(expandHome(keyring))
+	if err != nil {
+		return errors.Wrapf(err, "open keyring %s", keyring)
+	}
+	defer keyringFile.Close()
+
+	keyRing, err := openpgp.ReadKeyRing(keyringFile)
+	if err != nil {
+		return errors.Wrap(err, "read keyring")
+	}
+
+	if _, err := openpgp.CheckDetachedSignature(keyRing, bytes.NewReader(block.Bytes), block.ArmoredSignature.Body); err != nil {
+		return errors.Wrap(err, "verify signature")
+	}
+
+	return nil
+}
+
+// fileDigest extracts the sha256 digest recorded for filename in a
+// provenance file's "files:" block, e.g.:
+//
+//	files:
+//	  foo-1.2.3.tgz: sha256:1b2d3c...
+func fileDigest(plaintext []byte, filename string) (string, error) {
+	re := regexp.MustCompile(`(?m)^\s+` + regexp.QuoteMeta(filename) + `:\s*sha256:([0-9a-fA-F]{64})\s*$`)
+	match := re.FindSubmatch(plaintext)
+	if match == nil {
+		return "", errors.Errorf("provenance file does not list a sha256 digest for %s", filename)
+	}
+	return string(match[1]), nil
+}
+
+func downloadToTemp(sess *session.Session, bucket, key, name string) (string, error) {
+	tmp, err := ioutil.TempFile("", "helm-s3-verify-*-"+name)
+	if err != nil {
+		return "", err
+	}
+	defer tmp.Close()
+
+	downloader := s3manager.NewDownloaderWithClient(s3.New(sess))
+	if _, err := downloader.Download(tmp, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+
+	return tmp.Name(), nil
+}
+
+func expandHome(path string) string {
+	if len(path) >= 2 && path[:2] == "~/" {
+		if home, err := os.UserHomeDir(); err == nil {
+			return home + path[1:]
+		}
+	}
+	return path
+}
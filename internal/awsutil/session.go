@@ -0,0 +1,51 @@
+// Package awsutil provides helpers for constructing AWS clients shared by
+// the plugin's commands.
+package awsutil
+
+import (
+	"os"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/pkg/errors"
+)
+
+// New returns an AWS session configured from the environment (shared config,
+// shared credentials file, env vars, instance profile, etc.), the same way
+// the AWS CLI resolves credentials.
+//
+// If AWS_ENDPOINT_URL is set (or, failing that, E2E_S3_ENDPOINT, set by the
+// e2e test harness around a local minio instance), the session talks to
+// that endpoint instead of AWS directly, with path-style addressing forced
+// since S3-compatible servers generally don't support virtual-hosted-style
+// bucket URLs.
+func New() (*session.Session, error) {
+	cfg := aws.Config{}
+	if endpoint := testEndpoint(); endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		SharedConfigState: session.SharedConfigEnable,
+		Config:            cfg,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "create aws session")
+	}
+
+	return sess, nil
+}
+
+// testEndpoint returns the S3-compatible endpoint to use instead of AWS,
+// set by CI/local test harnesses. It returns "" when neither is set, which
+// leaves the session resolving against real AWS as usual.
+func testEndpoint() string {
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		return endpoint
+	}
+	if endpoint := os.Getenv("E2E_S3_ENDPOINT"); endpoint != "" {
+		return "http://" + endpoint
+	}
+	return ""
+}
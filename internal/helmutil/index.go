@@ -0,0 +1,62 @@
+package helmutil
+
+import (
+	"bytes"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// IndexObjectName is the name of the chart repository index file, always
+// stored at the root of the repository's prefix.
+const IndexObjectName = "index.yaml"
+
+// FetchIndex downloads and parses index.yaml from bucket/prefix.
+func FetchIndex(sess *session.Session, bucket, prefix string) (*repo.IndexFile, error) {
+	buf := &aws.WriteAtBuffer{}
+	downloader := s3manager.NewDownloader(sess)
+
+	if _, err := downloader.Download(buf, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(JoinKey(prefix, IndexObjectName)),
+	}); err != nil {
+		return nil, errors.Wrap(err, "download index.yaml")
+	}
+
+	idx := &repo.IndexFile{}
+	if err := yaml.Unmarshal(buf.Bytes(), idx); err != nil {
+		return nil, errors.Wrap(err, "parse index.yaml")
+	}
+	idx.SortEntries()
+
+	return idx, nil
+}
+
+// SaveIndex serializes idx and uploads it to bucket/prefix, overwriting the
+// existing index.yaml with a single PUT.
+func SaveIndex(sess *session.Session, bucket, prefix string, idx *repo.IndexFile) error {
+	idx.SortEntries()
+
+	raw, err := yaml.Marshal(idx)
+	if err != nil {
+		return errors.Wrap(err, "marshal index.yaml")
+	}
+
+	uploader := s3manager.NewUploader(sess)
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(JoinKey(prefix, IndexObjectName)),
+		Body:        bytes.NewReader(raw),
+		ContentType: aws.String("application/octet-stream"),
+	})
+	if err != nil {
+		return errors.Wrap(err, "upload index.yaml")
+	}
+
+	return nil
+}
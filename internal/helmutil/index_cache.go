@@ -0,0 +1,52 @@
+package helmutil
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+	"sigs.k8s.io/yaml"
+)
+
+// cachedIndexPath returns the path Helm itself would cache repoName's
+// index.yaml at after a `helm repo update`.
+func cachedIndexPath(repoName string) string {
+	return helmpath.CachePath("repository", repoName+"-index.yaml")
+}
+
+// LoadCachedIndex reads repoName's index.yaml from Helm's local repository
+// cache, if present. It returns (nil, nil) when there is no cache yet,
+// letting callers fall back to fetching a fresh copy from S3.
+func LoadCachedIndex(repoName string) (*repo.IndexFile, error) {
+	raw, err := os.ReadFile(cachedIndexPath(repoName))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "read cached index.yaml")
+	}
+
+	idx := &repo.IndexFile{}
+	if err := yaml.Unmarshal(raw, idx); err != nil {
+		return nil, errors.Wrap(err, "parse cached index.yaml")
+	}
+	idx.SortEntries()
+
+	return idx, nil
+}
+
+// CacheIndex writes idx to Helm's local repository cache for repoName so
+// subsequent pulls don't need to hit S3 just to resolve a chart version.
+func CacheIndex(repoName string, idx *repo.IndexFile) error {
+	raw, err := yaml.Marshal(idx)
+	if err != nil {
+		return errors.Wrap(err, "marshal index.yaml")
+	}
+
+	if err := os.WriteFile(cachedIndexPath(repoName), raw, 0644); err != nil {
+		return errors.Wrap(err, "write cached index.yaml")
+	}
+
+	return nil
+}
@@ -0,0 +1,59 @@
+// Package helmutil contains helpers for resolving helm-s3 repositories and
+// manipulating their index.yaml in S3.
+package helmutil
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// LookupRepoEntry returns the repository entry registered under name in the
+// user's repositories.yaml, as created by `helm repo add`.
+func LookupRepoEntry(name string) (*repo.Entry, error) {
+	repoFile, err := repo.LoadFile(helmpath.ConfigPath("repositories.yaml"))
+	if err != nil {
+		return nil, errors.Wrap(err, "load repositories file")
+	}
+
+	entry := repoFile.Get(name)
+	if entry == nil {
+		return nil, errors.Errorf("repository %q not found, did you add it with `helm repo add`?", name)
+	}
+
+	return entry, nil
+}
+
+// SplitS3URL splits a s3://bucket/key/prefix URL into its bucket and key
+// (prefix) parts. The key may be empty if the repository lives at the
+// bucket root.
+func SplitS3URL(url string) (bucket, key string, err error) {
+	trimmed := strings.TrimPrefix(url, "s3://")
+	if trimmed == url {
+		return "", "", errors.Errorf("invalid s3 url %q: missing s3:// scheme", url)
+	}
+
+	parts := strings.SplitN(trimmed, "/", 2)
+	bucket = parts[0]
+	if len(parts) == 2 {
+		key = parts[1]
+	}
+
+	return bucket, key, nil
+}
+
+// JoinKey joins non-empty path segments with "/", mirroring how S3 object
+// keys are built from a repository prefix and an object name.
+func JoinKey(parts ...string) string {
+	var nonEmpty []string
+	for _, p := range parts {
+		if p == "" {
+			continue
+		}
+		nonEmpty = append(nonEmpty, strings.Trim(p, "/"))
+	}
+
+	return strings.Join(nonEmpty, "/")
+}
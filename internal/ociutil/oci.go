@@ -0,0 +1,82 @@
+// Package ociutil implements just enough of the OCI distribution and image
+// spec for helm-s3 to store charts as OCI artifacts in an S3 bucket,
+// following the same directory layout a real OCI registry would expose:
+// manifests under "v2/<name>/manifests/<tag>" and content-addressed blobs
+// under "v2/<name>/blobs/sha256:<digest>".
+package ociutil
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// MediaTypeManifest is the media type of the artifact manifest itself.
+	MediaTypeManifest = "application/vnd.oci.image.manifest.v1+json"
+	// MediaTypeChartConfig is the media type of a chart's config blob, as
+	// used by Helm's own OCI support.
+	MediaTypeChartConfig = "application/vnd.cncf.helm.config.v1+json"
+	// MediaTypeChartLayer is the media type of the chart archive layer.
+	MediaTypeChartLayer = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+)
+
+// Descriptor identifies a blob by digest, size and media type, exactly as
+// it appears in a manifest.
+type Descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+// Manifest is a minimal OCI image manifest: a config blob plus a list of
+// content layers.
+type Manifest struct {
+	SchemaVersion int          `json:"schemaVersion"`
+	MediaType     string       `json:"mediaType"`
+	Config        Descriptor   `json:"config"`
+	Layers        []Descriptor `json:"layers"`
+}
+
+// ChartConfig is the minimal config blob Helm writes for a chart pushed in
+// OCI mode.
+type ChartConfig struct {
+	Name        string `json:"name"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// Digest returns the "sha256:<hex>" digest of b.
+func Digest(b []byte) string {
+	sum := sha256.Sum256(b)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// DescriptorFor builds the Descriptor for a blob's raw bytes.
+func DescriptorFor(mediaType string, b []byte) Descriptor {
+	return Descriptor{
+		MediaType: mediaType,
+		Digest:    Digest(b),
+		Size:      int64(len(b)),
+	}
+}
+
+// MarshalManifest serializes m the same way it will be stored in S3.
+func MarshalManifest(m Manifest) ([]byte, error) {
+	raw, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, errors.Wrap(err, "marshal oci manifest")
+	}
+	return raw, nil
+}
+
+// UnmarshalManifest parses a manifest previously written by MarshalManifest.
+func UnmarshalManifest(raw []byte) (Manifest, error) {
+	var m Manifest
+	if err := json.Unmarshal(raw, &m); err != nil {
+		return Manifest{}, errors.Wrap(err, "parse oci manifest")
+	}
+	return m, nil
+}
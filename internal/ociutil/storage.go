@@ -0,0 +1,97 @@
+package ociutil
+
+import (
+	"bytes"
+	"path"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/pkg/errors"
+)
+
+// BlobKey returns the S3 key a blob with the given digest is stored under
+// for the named chart repository, following the distribution-spec layout.
+func BlobKey(prefix, name, digest string) string {
+	return path.Join(prefix, "v2", name, "blobs", digest)
+}
+
+// ManifestKey returns the S3 key a tagged manifest is stored under for the
+// named chart repository.
+func ManifestKey(prefix, name, tag string) string {
+	return path.Join(prefix, "v2", name, "manifests", tag)
+}
+
+// PutBlob uploads b's content keyed by its own digest and returns the
+// resulting descriptor.
+func PutBlob(sess *session.Session, bucket, prefix, name string, b []byte, mediaType string) (Descriptor, error) {
+	desc := DescriptorFor(mediaType, b)
+
+	uploader := s3manager.NewUploader(sess)
+	_, err := uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(BlobKey(prefix, name, desc.Digest)),
+		Body:        bytes.NewReader(b),
+		ContentType: aws.String(mediaType),
+	})
+	if err != nil {
+		return Descriptor{}, errors.Wrapf(err, "upload blob %s", desc.Digest)
+	}
+
+	return desc, nil
+}
+
+// GetBlob downloads the blob identified by digest for the named chart
+// repository.
+func GetBlob(sess *session.Session, bucket, prefix, name, digest string) ([]byte, error) {
+	buf := &aws.WriteAtBuffer{}
+	downloader := s3manager.NewDownloader(sess)
+
+	if _, err := downloader.Download(buf, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(BlobKey(prefix, name, digest)),
+	}); err != nil {
+		return nil, errors.Wrapf(err, "download blob %s", digest)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// PutManifest uploads manifest, overwriting whatever tag it was previously
+// pushed under.
+func PutManifest(sess *session.Session, bucket, prefix, name, tag string, manifest Manifest) error {
+	raw, err := MarshalManifest(manifest)
+	if err != nil {
+		return err
+	}
+
+	uploader := s3manager.NewUploader(sess)
+	_, err = uploader.Upload(&s3manager.UploadInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(ManifestKey(prefix, name, tag)),
+		Body:        bytes.NewReader(raw),
+		ContentType: aws.String(MediaTypeManifest),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "upload manifest %s:%s", name, tag)
+	}
+
+	return nil
+}
+
+// GetManifest downloads and parses the manifest tagged tag for the named
+// chart repository.
+func GetManifest(sess *session.Session, bucket, prefix, name, tag string) (Manifest, error) {
+	buf := &aws.WriteAtBuffer{}
+	downloader := s3manager.NewDownloader(sess)
+
+	if _, err := downloader.Download(buf, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(ManifestKey(prefix, name, tag)),
+	}); err != nil {
+		return Manifest{}, errors.Wrapf(err, "download manifest %s:%s", name, tag)
+	}
+
+	return UnmarshalManifest(buf.Bytes())
+}
@@ -0,0 +1,189 @@
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/minio-go/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/sonjek/helm-s3/internal/ociutil"
+)
+
+func TestPushOCI(t *testing.T) {
+	t.Log("Test push action with --oci stores the chart as an OCI artifact")
+
+	const (
+		repoName      = "test-push-oci"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 push --oci %s %s", chartFilepath, repoName))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Successfully uploaded the chart to the repository.")
+
+	// Fetch and parse the manifest directly, the way any OCI-aware tool
+	// would, rather than through the plugin.
+
+	manifestKey := fmt.Sprintf("%s/v2/%s/manifests/%s", repoDir, chartName, chartVersion)
+
+	tmpdir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	manifestFile := tmpdir + "/manifest.json"
+	require.NoError(t, mc.FGetObject(repoName, manifestKey, manifestFile, minio.GetObjectOptions{}))
+
+	raw, err := ioutil.ReadFile(manifestFile)
+	require.NoError(t, err)
+
+	manifest, err := ociutil.UnmarshalManifest(raw)
+	require.NoError(t, err)
+	require.Len(t, manifest.Layers, 1)
+	assert.Equal(t, ociutil.MediaTypeChartLayer, manifest.Layers[0].MediaType)
+
+	// Pull the chart layer back by digest and check it matches the
+	// original chart bytes exactly.
+
+	layerKey := fmt.Sprintf("%s/v2/%s/blobs/%s", repoDir, chartName, manifest.Layers[0].Digest)
+
+	layerFile := tmpdir + "/layer.tgz"
+	require.NoError(t, mc.FGetObject(repoName, layerKey, layerFile, minio.GetObjectOptions{}))
+
+	gotBytes, err := ioutil.ReadFile(layerFile)
+	require.NoError(t, err)
+
+	wantBytes, err := ioutil.ReadFile(chartFilepath)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.Equal(wantBytes, gotBytes))
+	assert.Equal(t, ociutil.Digest(wantBytes), manifest.Layers[0].Digest)
+}
+
+func TestPullOCI(t *testing.T) {
+	t.Log("Test pull action with --oci resolves the manifest and fetches the chart layer by digest")
+
+	const (
+		repoName      = "test-pull-oci"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push --oci %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	tmpdir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 pull %s/%s --version %s --destination %s --oci", repoName, chartName, chartVersion, tmpdir))
+	err = cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Successfully pulled the chart.")
+
+	gotBytes, err := ioutil.ReadFile(filepath.Join(tmpdir, chartFilename))
+	require.NoError(t, err)
+
+	wantBytes, err := ioutil.ReadFile(chartFilepath)
+	require.NoError(t, err)
+
+	assert.True(t, bytes.Equal(wantBytes, gotBytes))
+}
+
+func TestPullOCIDigestMismatch(t *testing.T) {
+	t.Log("Test pull action with --oci rejects a chart layer that no longer matches its manifest digest")
+
+	const (
+		repoName      = "test-pull-oci-digest-mismatch"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push --oci %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	manifestKey := fmt.Sprintf("%s/v2/%s/manifests/%s", repoDir, chartName, chartVersion)
+
+	tmpdir, err := ioutil.TempDir("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	manifestFile := tmpdir + "/manifest.json"
+	require.NoError(t, mc.FGetObject(repoName, manifestKey, manifestFile, minio.GetObjectOptions{}))
+
+	raw, err := ioutil.ReadFile(manifestFile)
+	require.NoError(t, err)
+
+	manifest, err := ociutil.UnmarshalManifest(raw)
+	require.NoError(t, err)
+	require.Len(t, manifest.Layers, 1)
+
+	// Tamper with the chart layer blob directly, bypassing the plugin, so
+	// its bytes no longer match the digest recorded in the manifest.
+	layerKey := fmt.Sprintf("%s/v2/%s/blobs/%s", repoDir, chartName, manifest.Layers[0].Digest)
+	tampered := []byte("not the original chart bytes")
+	_, err = mc.PutObject(repoName, layerKey, bytes.NewReader(tampered), int64(len(tampered)), minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	destination := tmpdir + "/dest"
+	require.NoError(t, os.Mkdir(destination, 0755))
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 pull %s/%s --version %s --destination %s --oci", repoName, chartName, chartVersion, destination))
+	err = cmd.Run()
+	assert.Error(t, err)
+	assertEmptyOutput(t, stdout, nil)
+	assert.Contains(t, stderr.String(), "chart digest mismatch")
+	assert.NoFileExists(t, filepath.Join(destination, chartFilename))
+}
+
+func TestInitOCI(t *testing.T) {
+	t.Log("Test init action with --oci does not create an index.yaml")
+
+	const (
+		repoName = "test-init-oci"
+		repoDir  = "charts"
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 init --oci s3://%s/%s", repoName, repoDir))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Completed successfully.")
+
+	_, err = mc.StatObject(repoName, repoDir+"/index.yaml", minio.StatObjectOptions{})
+	assert.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+}
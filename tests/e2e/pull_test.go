@@ -0,0 +1,83 @@
+package e2e
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/minio/minio-go/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPull(t *testing.T) {
+	t.Log("Test pull action fetches a chart directly from S3")
+
+	const (
+		repoName      = "test-pull"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	tmpdir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 pull %s/%s --version %s --destination %s", repoName, chartName, chartVersion, tmpdir))
+	err = cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Successfully pulled the chart.")
+	assert.FileExists(t, filepath.Join(tmpdir, chartFilename))
+}
+
+func TestPullDigestMismatch(t *testing.T) {
+	t.Log("Test pull action rejects a chart that was tampered with after being indexed")
+
+	const (
+		repoName      = "test-pull-digest-mismatch"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	// Tamper with the chart object directly, bypassing the plugin, so its
+	// bytes no longer match the digest recorded in index.yaml.
+	tampered := []byte("not the original chart bytes")
+	_, err := mc.PutObject(repoName, repoDir+"/"+chartFilename, bytes.NewReader(tampered), int64(len(tampered)), minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	tmpdir, err := os.MkdirTemp("", t.Name())
+	require.NoError(t, err)
+	defer os.RemoveAll(tmpdir)
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 pull %s/%s --version %s --destination %s", repoName, chartName, chartVersion, tmpdir))
+	err = cmd.Run()
+	assert.Error(t, err)
+	assertEmptyOutput(t, stdout, nil)
+	assert.Contains(t, stderr.String(), "chart digest mismatch")
+	assert.NoFileExists(t, filepath.Join(tmpdir, chartFilename))
+}
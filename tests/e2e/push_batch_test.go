@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/minio/minio-go/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestPushRecursive(t *testing.T) {
+	t.Log("Test push action with --recursive flag pushes every chart in a directory")
+
+	const (
+		repoName  = "test-push-recursive"
+		repoDir   = "charts"
+		chartsDir = "testdata/batch"
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 push --recursive %s %s", chartsDir, repoName))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Summary:")
+
+	// Check that every chart in the directory landed in the bucket.
+
+	for _, name := range []string{"foo-1.2.3.tgz", "bar-2.0.0.tgz"} {
+		_, err := mc.StatObject(repoName, repoDir+"/"+name, minio.StatObjectOptions{})
+		assert.NoError(t, err, "expected %s to have been pushed", name)
+	}
+
+	// Check that a single index.yaml now lists both charts.
+
+	tmpdir := t.TempDir()
+	indexFile := tmpdir + "/index.yaml"
+
+	err = mc.FGetObject(repoName, repoDir+"/index.yaml", indexFile, minio.GetObjectOptions{})
+	require.NoError(t, err)
+
+	idx, err := repo.LoadIndexFile(indexFile)
+	require.NoError(t, err)
+
+	assert.True(t, idx.Has("foo", "1.2.3"))
+	assert.True(t, idx.Has("bar", "2.0.0"))
+}
+
+func TestPushBatchDryRun(t *testing.T) {
+	t.Log("Test push action with --recursive and --dry-run uploads nothing")
+
+	const (
+		repoName  = "test-push-batch-dry-run"
+		repoDir   = "charts"
+		chartsDir = "testdata/batch"
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 push --recursive --dry-run %s %s", chartsDir, repoName))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Summary:")
+
+	for _, name := range []string{"foo-1.2.3.tgz", "bar-2.0.0.tgz"} {
+		_, err := mc.StatObject(repoName, repoDir+"/"+name, minio.StatObjectOptions{})
+		assert.Equal(t, "NoSuchKey", minio.ToErrorResponse(err).Code)
+	}
+}
@@ -0,0 +1,139 @@
+package e2e
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/minio/minio-go/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestReindexMultipleRepos(t *testing.T) {
+	t.Log("Test reindex action rebuilds the index for several repos, reconciling orphans")
+
+	const (
+		repoNameA = "test-reindex-a"
+		repoNameB = "test-reindex-b"
+		repoDir   = "charts"
+	)
+
+	setupRepo(t, repoNameA, repoDir)
+	defer teardownRepo(t, repoNameA)
+	setupRepo(t, repoNameB, repoDir)
+	defer teardownRepo(t, repoNameB)
+
+	// Push a chart normally to repo A so its index already has an entry.
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", "testdata/foo-1.2.3.tgz", repoNameA))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	// Upload a chart object directly to repo B's bucket, bypassing push, so
+	// its index.yaml does not know about it yet (an "orphan object").
+	_, err := mc.FPutObject(repoNameB, repoDir+"/foo-1.2.3.tgz", "testdata/foo-1.2.3.tgz", minio.PutObjectOptions{
+		ContentType: defaultChartsContentType,
+	})
+	require.NoError(t, err)
+
+	// Seed repo B's index.yaml with an orphan entry pointing at an object
+	// that was never uploaded.
+	orphanIdx := repo.NewIndexFile()
+	orphanIdx.Add(&chart.Metadata{Name: "ghost", Version: "9.9.9"}, "ghost-9.9.9.tgz", "s3://"+repoNameB+"/"+repoDir, "deadbeef")
+
+	tmpIndex := t.TempDir() + "/index.yaml"
+	require.NoError(t, orphanIdx.WriteFile(tmpIndex, 0644))
+	_, err = mc.FPutObject(repoNameB, repoDir+"/index.yaml", tmpIndex, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 reindex %s %s --prune", repoNameA, repoNameB))
+	err = cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), repoNameA)
+	assert.Contains(t, stdout.String(), repoNameB)
+
+	// Repo B's rebuilt index should contain the orphan object's chart and no
+	// longer contain the entry with no backing object.
+	tmpdir := t.TempDir()
+	indexFile := tmpdir + "/index.yaml"
+
+	require.NoError(t, mc.FGetObject(repoNameB, repoDir+"/index.yaml", indexFile, minio.GetObjectOptions{}))
+
+	idx, err := repo.LoadIndexFile(indexFile)
+	require.NoError(t, err)
+
+	assert.True(t, idx.Has("foo", "1.2.3"))
+	assert.False(t, idx.Has("ghost", "9.9.9"))
+}
+
+func TestReindexWithoutPrune(t *testing.T) {
+	t.Log("Test reindex action without --prune keeps entries with no backing object")
+
+	const (
+		repoName = "test-reindex-no-prune"
+		repoDir  = "charts"
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	// Seed the index with an orphan entry pointing at an object that was
+	// never uploaded.
+	orphanIdx := repo.NewIndexFile()
+	orphanIdx.Add(&chart.Metadata{Name: "ghost", Version: "9.9.9"}, "ghost-9.9.9.tgz", "s3://"+repoName+"/"+repoDir, "deadbeef")
+
+	tmpIndex := t.TempDir() + "/index.yaml"
+	require.NoError(t, orphanIdx.WriteFile(tmpIndex, 0644))
+	_, err := mc.FPutObject(repoName, repoDir+"/index.yaml", tmpIndex, minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 reindex %s", repoName))
+	err = cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+
+	tmpdir := t.TempDir()
+	indexFile := tmpdir + "/index.yaml"
+	require.NoError(t, mc.FGetObject(repoName, repoDir+"/index.yaml", indexFile, minio.GetObjectOptions{}))
+
+	idx, err := repo.LoadIndexFile(indexFile)
+	require.NoError(t, err)
+
+	assert.True(t, idx.Has("ghost", "9.9.9"), "expected orphan entry to be kept without --prune")
+}
+
+func TestReindexDryRun(t *testing.T) {
+	t.Log("Test reindex action with --dry-run prints a diff without uploading a new index")
+
+	const (
+		repoName = "test-reindex-dry-run"
+		repoDir  = "charts"
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", "testdata/foo-1.2.3.tgz", repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	tmpdir := t.TempDir()
+	indexFile := tmpdir + "/index.yaml"
+	require.NoError(t, mc.FGetObject(repoName, repoDir+"/index.yaml", indexFile, minio.GetObjectOptions{}))
+	before, err := repo.LoadIndexFile(indexFile)
+	require.NoError(t, err)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 reindex %s --dry-run", repoName))
+	err = cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), repoName)
+
+	require.NoError(t, mc.FGetObject(repoName, repoDir+"/index.yaml", indexFile, minio.GetObjectOptions{}))
+	after, err := repo.LoadIndexFile(indexFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, before.Generated, after.Generated)
+}
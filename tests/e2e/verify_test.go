@@ -0,0 +1,103 @@
+package e2e
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/minio/minio-go/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPushProvenance(t *testing.T) {
+	t.Log("Test push action uploads the sibling provenance file")
+
+	const (
+		repoName        = "test-push-provenance"
+		repoDir         = "charts"
+		chartFilename   = "foo-1.2.3.tgz"
+		provFilename    = chartFilename + ".prov"
+		chartFilepath   = "testdata/" + chartFilename
+		chartObjectName = repoDir + "/" + chartFilename
+		provObjectName  = repoDir + "/" + provFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, stdout, stderr := command(fmt.Sprintf("helm s3 push %s %s", chartFilepath, repoName))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Successfully uploaded the chart to the repository.")
+
+	// Check that both the chart and its provenance file were uploaded.
+
+	_, err = mc.StatObject(repoName, chartObjectName, minio.StatObjectOptions{})
+	assert.NoError(t, err)
+
+	obj, err := mc.StatObject(repoName, provObjectName, minio.StatObjectOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "application/pgp-signature", obj.ContentType)
+}
+
+func TestVerify(t *testing.T) {
+	t.Log("Test verify action validates a pushed, signed chart")
+
+	const (
+		repoName      = "test-verify"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 verify %s/%s --version %s --keyring testdata/pubring.gpg", repoName, chartName, chartVersion))
+	err := cmd.Run()
+	assert.NoError(t, err)
+	assertEmptyOutput(t, nil, stderr)
+	assert.Contains(t, stdout.String(), "Signed chart is valid.")
+}
+
+func TestVerifyTamperedChart(t *testing.T) {
+	t.Log("Test verify action rejects a chart whose digest does not match its provenance file")
+
+	const (
+		repoName      = "test-verify-tampered"
+		repoDir       = "charts"
+		chartName     = "foo"
+		chartVersion  = "1.2.3"
+		chartFilename = "foo-1.2.3.tgz"
+		chartFilepath = "testdata/" + chartFilename
+	)
+
+	setupRepo(t, repoName, repoDir)
+	defer teardownRepo(t, repoName)
+
+	cmd, _, stderr := command(fmt.Sprintf("helm s3 push %s %s", chartFilepath, repoName))
+	require.NoError(t, cmd.Run())
+	assertEmptyOutput(t, nil, stderr)
+
+	// Tamper with the uploaded chart so its digest no longer matches the
+	// one recorded in the provenance file.
+	tampered := strings.NewReader("not the original chart bytes")
+	_, err := mc.PutObject(repoName, repoDir+"/"+chartFilename, tampered, tampered.Size(), minio.PutObjectOptions{})
+	require.NoError(t, err)
+
+	cmd, stdout, stderr := command(fmt.Sprintf(
+		"helm s3 verify %s/%s --version %s --keyring testdata/pubring.gpg", repoName, chartName, chartVersion))
+	err = cmd.Run()
+	assert.Error(t, err)
+	assertEmptyOutput(t, stdout, nil)
+	assert.Contains(t, stderr.String(), "chart digest mismatch")
+}